@@ -0,0 +1,230 @@
+//go:build amd64 || arm64
+
+package bootc
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// cacheManifestName is the name of the content-addressed cache's index file,
+// stored alongside the cached disk images in vmrunCacheDir.
+const cacheManifestName = "manifest.json"
+
+// cacheEntry records what a single (image name, digest) pair resolved to the
+// last time getOrCreateDiskImage ran.
+type cacheEntry struct {
+	// Digest is the container image digest this entry was built from.
+	Digest string `json:"digest"`
+	// Path is the content-addressed disk image file, named
+	// <sha256(digest)>.raw. Multiple image names that resolve to the
+	// same digest share this same path.
+	Path string `json:"path"`
+	// Size is the size in bytes of the disk image at Path.
+	Size int64 `json:"size"`
+	// CreatedAt is when this disk image was built.
+	CreatedAt time.Time `json:"createdAt"`
+	// LastUsedAt is updated every time this entry is reused, and drives
+	// LRU eviction.
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	// BootcVersion is the `bootc --version` output of the image this
+	// disk was built from, if known.
+	BootcVersion string `json:"bootcVersion,omitempty"`
+	// KernelArgs records the kernel command line baked into the disk.
+	KernelArgs string `json:"kernelArgs,omitempty"`
+}
+
+// cacheManifest is the on-disk index of the bootc vmrun disk cache. It maps
+// image name -> digest -> cacheEntry.
+type cacheManifest struct {
+	Images map[string]map[string]*cacheEntry `json:"images"`
+}
+
+// withCacheLock runs fn while holding an exclusive flock on the cache
+// directory's lock file, serializing every load-mutate-save cycle over the
+// manifest. Without this, two concurrent `podman machine bootc` invocations
+// against the same cache dir (e.g. two vmrun's building different images at
+// once, exactly the workflow chunk0-2's transient-VM registry was built to
+// unblock) can race and silently clobber each other's manifest writes.
+func withCacheLock(cachedir string, fn func() error) error {
+	lockPath := filepath.Join(cachedir, cacheManifestName+".lock")
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer lockFile.Close()
+	if err := unix.Flock(int(lockFile.Fd()), unix.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+	defer unix.Flock(int(lockFile.Fd()), unix.LOCK_UN)
+	return fn()
+}
+
+func loadCacheManifest(cachedir string) (*cacheManifest, error) {
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+
+	buf, err := os.ReadFile(filepath.Join(cachedir, cacheManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", cacheManifestName, err)
+	}
+	if manifest.Images == nil {
+		manifest.Images = map[string]map[string]*cacheEntry{}
+	}
+	return manifest, nil
+}
+
+func (m *cacheManifest) save(cachedir string) error {
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(cachedir, cacheManifestName)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(cachedir, cacheManifestName))
+}
+
+// get returns the cache entry for imageName at digest, if any.
+func (m *cacheManifest) get(imageName, digest string) *cacheEntry {
+	return m.Images[imageName][digest]
+}
+
+// put records a cache entry for imageName at digest.
+func (m *cacheManifest) put(imageName string, entry *cacheEntry) {
+	if m.Images[imageName] == nil {
+		m.Images[imageName] = map[string]*cacheEntry{}
+	}
+	m.Images[imageName][entry.Digest] = entry
+}
+
+// contentPath returns the content-addressed path a disk image for digest
+// should live at. Image names that resolve to the same digest naturally
+// share this same file, so reusing a disk across tags never requires a copy
+// or a hardlink: there's only ever one file per digest to begin with.
+func contentPath(cachedir, digest string) string {
+	sum := sha256.Sum256([]byte(digest))
+	return filepath.Join(cachedir, fmt.Sprintf("%x.raw", sum))
+}
+
+// entriesByPath collapses the manifest down to one row per content-addressed
+// file, taking the most recent LastUsedAt across every (image, digest) pair
+// that references it.
+func (m *cacheManifest) entriesByPath() []*cacheEntry {
+	byPath := map[string]*cacheEntry{}
+	for _, digests := range m.Images {
+		for _, entry := range digests {
+			existing, ok := byPath[entry.Path]
+			if !ok || entry.LastUsedAt.After(existing.LastUsedAt) {
+				byPath[entry.Path] = entry
+			}
+		}
+	}
+	entries := make([]*cacheEntry, 0, len(byPath))
+	for _, entry := range byPath {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsedAt.Before(entries[j].LastUsedAt)
+	})
+	return entries
+}
+
+// removePath deletes path from disk and drops every manifest entry that
+// referenced it, regardless of which image(s) they belong to. This is only
+// correct for callers that mean to discard the content-addressed file
+// outright, e.g. evictLRU and `cache prune`; `cache rm <image>` must use
+// removeImageEntry instead, since a file can be shared by other images.
+func (m *cacheManifest) removePath(path string) error {
+	for imageName, digests := range m.Images {
+		for digest, entry := range digests {
+			if entry.Path == path {
+				delete(digests, digest)
+			}
+		}
+		if len(digests) == 0 {
+			delete(m.Images, imageName)
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// removeImageEntry drops only imageName's entry for digest from the
+// manifest. Because disk images are content-addressed, the underlying file
+// at path is only unlinked once no other (image, digest) pair in the
+// manifest still references it.
+func (m *cacheManifest) removeImageEntry(imageName, digest, path string) error {
+	if digests := m.Images[imageName]; digests != nil {
+		delete(digests, digest)
+		if len(digests) == 0 {
+			delete(m.Images, imageName)
+		}
+	}
+	for _, digests := range m.Images {
+		for _, entry := range digests {
+			if entry.Path == path {
+				return nil
+			}
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// evictLRU removes the least-recently-used cache entries until the total
+// size of the cache is at or below maxBytes. excludePath, when non-empty, is
+// never evicted even if it would otherwise be the next LRU candidate: it's
+// meant for the entry createDiskImage just built, which vmrun still needs to
+// clone from in order to boot, so evicting it out from under that caller
+// would turn the cache-size flag into a self-inflicted "file not found".
+func evictLRU(cachedir string, manifest *cacheManifest, maxBytes int64, excludePath string) error {
+	if maxBytes <= 0 {
+		return nil
+	}
+	entries := manifest.entriesByPath()
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	for _, entry := range entries {
+		if total <= maxBytes {
+			break
+		}
+		if entry.Path == excludePath {
+			continue
+		}
+		logrus.Infof("evicting cached bootc disk %s (%d bytes, last used %s)", entry.Path, entry.Size, entry.LastUsedAt)
+		if err := manifest.removePath(entry.Path); err != nil {
+			return err
+		}
+		total -= entry.Size
+	}
+	return manifest.save(cachedir)
+}
@@ -0,0 +1,148 @@
+//go:build amd64 || arm64
+
+package bootc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	cmdmachine "github.com/containers/podman/v4/cmd/podman/machine"
+	"github.com/containers/podman/v4/cmd/podman/registry"
+	"github.com/containers/podman/v4/cmd/podman/validate"
+	"github.com/containers/podman/v4/pkg/machine"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheCommand = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the bootc vmrun disk cache",
+		RunE:  validate.SubCommandExists,
+	}
+
+	cacheLsCommand = &cobra.Command{
+		Use:   "ls",
+		Short: "List cached bootc vmrun disk images",
+		Args:  cobra.NoArgs,
+		RunE:  cacheLs,
+	}
+
+	cacheRmCommand = &cobra.Command{
+		Use:   "rm IMAGE",
+		Short: "Remove a cached bootc vmrun disk image",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cacheRm,
+	}
+
+	cachePruneCommand = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove every cached bootc vmrun disk image",
+		Args:  cobra.NoArgs,
+		RunE:  cachePrune,
+	}
+)
+
+func init() {
+	registry.Commands = append(registry.Commands,
+		registry.CliCommand{Command: cacheCommand, Parent: cmdmachine.BootcCommand},
+		registry.CliCommand{Command: cacheLsCommand, Parent: cacheCommand},
+		registry.CliCommand{Command: cacheRmCommand, Parent: cacheCommand},
+		registry.CliCommand{Command: cachePruneCommand, Parent: cacheCommand},
+	)
+}
+
+func vmrunCacheDirPath() (string, error) {
+	datadir, err := machine.GetGlobalDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(datadir, vmrunCacheDir)
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+func cacheLs(cmd *cobra.Command, args []string) error {
+	cachedir, err := vmrunCacheDirPath()
+	if err != nil {
+		return err
+	}
+	manifest, err := loadCacheManifest(cachedir)
+	if err != nil {
+		return err
+	}
+
+	imageNames := make([]string, 0, len(manifest.Images))
+	for imageName := range manifest.Images {
+		imageNames = append(imageNames, imageName)
+	}
+	sort.Strings(imageNames)
+
+	for _, imageName := range imageNames {
+		digests := manifest.Images[imageName]
+		digestKeys := make([]string, 0, len(digests))
+		for digest := range digests {
+			digestKeys = append(digestKeys, digest)
+		}
+		sort.Strings(digestKeys)
+
+		for _, digest := range digestKeys {
+			entry := digests[digest]
+			bootcVersion := entry.BootcVersion
+			if bootcVersion == "" {
+				bootcVersion = "unknown"
+			}
+			fmt.Printf("%s@%s\t%s\t%s\t%s\n", imageName, digest, units.HumanSize(float64(entry.Size)), bootcVersion, entry.LastUsedAt.Format("2006-01-02T15:04:05Z07:00"))
+		}
+	}
+	return nil
+}
+
+func cacheRm(cmd *cobra.Command, args []string) error {
+	imageName := args[0]
+
+	cachedir, err := vmrunCacheDirPath()
+	if err != nil {
+		return err
+	}
+
+	return withCacheLock(cachedir, func() error {
+		manifest, err := loadCacheManifest(cachedir)
+		if err != nil {
+			return err
+		}
+
+		digests, ok := manifest.Images[imageName]
+		if !ok {
+			return fmt.Errorf("no cached disk image for %s", imageName)
+		}
+		for digest, entry := range digests {
+			if err := manifest.removeImageEntry(imageName, digest, entry.Path); err != nil {
+				return err
+			}
+		}
+		return manifest.save(cachedir)
+	})
+}
+
+func cachePrune(cmd *cobra.Command, args []string) error {
+	cachedir, err := vmrunCacheDirPath()
+	if err != nil {
+		return err
+	}
+
+	return withCacheLock(cachedir, func() error {
+		manifest, err := loadCacheManifest(cachedir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range manifest.entriesByPath() {
+			if err := manifest.removePath(entry.Path); err != nil {
+				return err
+			}
+		}
+		return manifest.save(cachedir)
+	})
+}
@@ -0,0 +1,194 @@
+//go:build amd64 || arm64
+
+package bootc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("disk"), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestCacheManifestGetPut(t *testing.T) {
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+	if entry := manifest.get("image", "digest"); entry != nil {
+		t.Fatalf("get on empty manifest = %+v, want nil", entry)
+	}
+
+	entry := &cacheEntry{Digest: "digest", Path: "/cache/abc.raw"}
+	manifest.put("image", entry)
+
+	got := manifest.get("image", "digest")
+	if got != entry {
+		t.Fatalf("get(%q, %q) = %+v, want %+v", "image", "digest", got, entry)
+	}
+}
+
+func TestContentPathIsDeterministicAndDigestScoped(t *testing.T) {
+	a := contentPath("/cache", "sha256:aaa")
+	b := contentPath("/cache", "sha256:aaa")
+	if a != b {
+		t.Fatalf("contentPath is not deterministic: %q != %q", a, b)
+	}
+	if c := contentPath("/cache", "sha256:bbb"); c == a {
+		t.Fatalf("contentPath collided for different digests: %q", c)
+	}
+}
+
+func TestEntriesByPathCollapsesSharedDigests(t *testing.T) {
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+	manifest.put("imageA:latest", &cacheEntry{Digest: "sha256:same", Path: "/cache/x.raw", LastUsedAt: older})
+	manifest.put("imageB:latest", &cacheEntry{Digest: "sha256:same", Path: "/cache/x.raw", LastUsedAt: newer})
+
+	entries := manifest.entriesByPath()
+	if len(entries) != 1 {
+		t.Fatalf("entriesByPath() returned %d entries, want 1 for a shared path", len(entries))
+	}
+	if !entries[0].LastUsedAt.Equal(newer) {
+		t.Fatalf("entriesByPath() kept LastUsedAt %v, want the most recent %v", entries[0].LastUsedAt, newer)
+	}
+}
+
+func TestRemovePathDropsEveryReferencingEntry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shared.raw")
+	writeFile(t, path)
+
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+	manifest.put("imageA:latest", &cacheEntry{Digest: "sha256:same", Path: path})
+	manifest.put("imageB:latest", &cacheEntry{Digest: "sha256:same", Path: path})
+
+	if err := manifest.removePath(path); err != nil {
+		t.Fatalf("removePath() returned error: %v", err)
+	}
+	if _, ok := manifest.Images["imageA:latest"]; ok {
+		t.Error("removePath() left imageA's entry in place")
+	}
+	if _, ok := manifest.Images["imageB:latest"]; ok {
+		t.Error("removePath() left imageB's entry in place")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("removePath() did not remove %s from disk: %v", path, err)
+	}
+}
+
+func TestRemoveImageEntryOnlyDropsItsOwnImage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shared.raw")
+	writeFile(t, path)
+
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+	manifest.put("imageA:latest", &cacheEntry{Digest: "sha256:same", Path: path})
+	manifest.put("imageB:latest", &cacheEntry{Digest: "sha256:same", Path: path})
+
+	if err := manifest.removeImageEntry("imageA:latest", "sha256:same", path); err != nil {
+		t.Fatalf("removeImageEntry() returned error: %v", err)
+	}
+	if _, ok := manifest.Images["imageA:latest"]; ok {
+		t.Error("removeImageEntry() left imageA's own entry in place")
+	}
+	if _, ok := manifest.Images["imageB:latest"]; !ok {
+		t.Error("removeImageEntry() dropped imageB's entry, which still references the shared file")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("removeImageEntry() unlinked %s even though imageB still references it: %v", path, err)
+	}
+}
+
+func TestRemoveImageEntryUnlinksFileOnceUnreferenced(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "solo.raw")
+	writeFile(t, path)
+
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+	manifest.put("imageA:latest", &cacheEntry{Digest: "sha256:only", Path: path})
+
+	if err := manifest.removeImageEntry("imageA:latest", "sha256:only", path); err != nil {
+		t.Fatalf("removeImageEntry() returned error: %v", err)
+	}
+	if _, ok := manifest.Images["imageA:latest"]; ok {
+		t.Error("removeImageEntry() left imageA's entry in place")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("removeImageEntry() did not unlink unreferenced file %s: %v", path, err)
+	}
+}
+
+func TestEvictLRURemovesOldestUntilUnderBudget(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.raw")
+	newPath := filepath.Join(dir, "new.raw")
+	writeFile(t, oldPath)
+	writeFile(t, newPath)
+
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+	manifest.put("old:latest", &cacheEntry{Digest: "sha256:old", Path: oldPath, Size: 100, LastUsedAt: time.Now().Add(-time.Hour)})
+	manifest.put("new:latest", &cacheEntry{Digest: "sha256:new", Path: newPath, Size: 100, LastUsedAt: time.Now()})
+
+	if err := evictLRU(dir, manifest, 150, ""); err != nil {
+		t.Fatalf("evictLRU() returned error: %v", err)
+	}
+
+	if _, ok := manifest.Images["old:latest"]; ok {
+		t.Error("evictLRU() should have evicted the least-recently-used entry")
+	}
+	if _, ok := manifest.Images["new:latest"]; !ok {
+		t.Error("evictLRU() should have kept the most-recently-used entry")
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("evictLRU() did not remove %s from disk: %v", oldPath, err)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("evictLRU() unexpectedly removed %s: %v", newPath, err)
+	}
+}
+
+func TestEvictLRUNeverEvictsExcludedPath(t *testing.T) {
+	dir := t.TempDir()
+	excludedPath := filepath.Join(dir, "excluded.raw")
+	otherPath := filepath.Join(dir, "other.raw")
+	writeFile(t, excludedPath)
+	writeFile(t, otherPath)
+
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+	// excludedPath is the least-recently-used entry, so it would normally
+	// be evicted first; passing it as excludePath must skip it and evict
+	// otherPath instead to reach the budget.
+	manifest.put("excluded:latest", &cacheEntry{Digest: "sha256:excluded", Path: excludedPath, Size: 100, LastUsedAt: time.Now().Add(-time.Hour)})
+	manifest.put("other:latest", &cacheEntry{Digest: "sha256:other", Path: otherPath, Size: 100, LastUsedAt: time.Now()})
+
+	if err := evictLRU(dir, manifest, 100, excludedPath); err != nil {
+		t.Fatalf("evictLRU() returned error: %v", err)
+	}
+
+	if _, ok := manifest.Images["excluded:latest"]; !ok {
+		t.Error("evictLRU() evicted the excluded path even though it's the LRU candidate")
+	}
+	if _, err := os.Stat(excludedPath); err != nil {
+		t.Errorf("evictLRU() unexpectedly removed excluded path %s: %v", excludedPath, err)
+	}
+	if _, ok := manifest.Images["other:latest"]; ok {
+		t.Error("evictLRU() should have evicted the non-excluded entry to reach the budget")
+	}
+}
+
+func TestEvictLRUNoopWhenMaxBytesIsZero(t *testing.T) {
+	manifest := &cacheManifest{Images: map[string]map[string]*cacheEntry{}}
+	manifest.put("image:latest", &cacheEntry{Digest: "sha256:x", Path: "/cache/x.raw", Size: 100})
+
+	if err := evictLRU(t.TempDir(), manifest, 0, ""); err != nil {
+		t.Fatalf("evictLRU() returned error: %v", err)
+	}
+	if _, ok := manifest.Images["image:latest"]; !ok {
+		t.Error("evictLRU(maxBytes=0) should be a no-op")
+	}
+}
@@ -4,8 +4,6 @@ package bootc
 
 import (
 	"bytes"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,15 +11,16 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/containers/podman/v4/cmd/podman/common"
 	cmdmachine "github.com/containers/podman/v4/cmd/podman/machine"
 	"github.com/containers/podman/v4/cmd/podman/registry"
 	"github.com/containers/podman/v4/pkg/machine"
 	machinevirtprovider "github.com/containers/podman/v4/pkg/machine/provider"
+	"github.com/docker/go-units"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"golang.org/x/sys/unix"
 )
 
 // TODO get this from the container
@@ -30,8 +29,13 @@ const diskSize = 10 * 1024 * 1024 * 1024
 // vmrunCacheDir is placed under the machine dir
 const vmrunCacheDir = "bootc-vmrun"
 
-// imageMetaXattr holds serialized diskFromContainerMeta
-const imageMetaXattr = "user.bootc.meta"
+// defaultCacheSize bounds the bootc vmrun disk cache when --cache-size isn't given.
+const defaultCacheSize = 20 * 1024 * 1024 * 1024
+
+// bootcKernelArgs are the console kargs baked into every vmrun disk by
+// loopWrapperEntrypoint below, recorded alongside the disk in the cache
+// manifest for `podman machine bootc cache ls`.
+const bootcKernelArgs = "console=hvc0 console=ttyS0,114800n8 console=tty0"
 
 // loopWrapperEntrypoint sets up a loopback device
 const loopWrapperEntrypoint = `#!/bin/bash
@@ -53,12 +57,6 @@ losetup -d /dev/loop0
 exit $rc
 `
 
-// diskFromContainerMeta is serialized to JSON in a user xattr on a disk image
-type diskFromContainerMeta struct {
-	// imageDigest is the digested sha256 of the container that was used to build this disk
-	ImageDigest string `json:"imageDigest"`
-}
-
 type vmRunCtx struct {
 	cmd      *cobra.Command
 	cachedir string
@@ -67,6 +65,9 @@ type vmRunCtx struct {
 type optionsData struct {
 	bootcLogLevel string
 	vmDebug       bool
+	cacheSize     string
+	cpus          uint16
+	memoryMiB     uint64
 }
 
 var (
@@ -85,6 +86,9 @@ func init() {
 	flags := vmrunCommand.Flags()
 	flags.StringVar(&options.bootcLogLevel, "bootc-log-level", "", "Enable bootc install debugging")
 	flags.BoolVar(&options.vmDebug, "vmdebug", false, "Enable debugging for VM launching")
+	flags.StringVar(&options.cacheSize, "cache-size", "", "Maximum size of the bootc vmrun disk cache, e.g. 20GiB (default 20GiB)")
+	flags.Uint16Var(&options.cpus, "cpus", 2, "Number of CPUs for the transient VM")
+	flags.Uint64Var(&options.memoryMiB, "memory", 2048, "Memory in MiB for the transient VM")
 	registry.Commands = append(registry.Commands, registry.CliCommand{
 		Command: vmrunCommand,
 		Parent:  cmdmachine.BootcCommand,
@@ -115,7 +119,11 @@ func podmanRecurseRun(cmd *cobra.Command, args []string) error {
 	return c.Run()
 }
 
-func createDiskImage(ctx *vmRunCtx, imageName, imageDigest, targetDisk string) (string, error) {
+// createDiskImage builds a fresh disk image for imageName at imageDigest,
+// places it at its content-addressed path, and records it in manifest.
+func createDiskImage(ctx *vmRunCtx, manifest *cacheManifest, imageName, imageDigest string) (string, error) {
+	targetDisk := contentPath(ctx.cachedir, imageDigest)
+
 	temporaryDisk, err := os.CreateTemp(ctx.cachedir, "podman-bootc-tempdisk")
 	if err != nil {
 		return "", err
@@ -158,53 +166,111 @@ func createDiskImage(ctx *vmRunCtx, imageName, imageDigest, targetDisk string) (
 		return "", fmt.Errorf("failed to run container to generate temporary disk: %w", err)
 	}
 
-	doCleanupDisk = false
-	serializedMeta := diskFromContainerMeta{
-		ImageDigest: imageDigest,
-	}
-	buf, err := json.Marshal(serializedMeta)
+	info, err := temporaryDisk.Stat()
 	if err != nil {
 		return "", err
 	}
-	if err := unix.Fsetxattr(int(temporaryDisk.Fd()), imageMetaXattr, buf, 0); err != nil {
-		return "", fmt.Errorf("failed to set xattr: %w", err)
-	}
+
+	doCleanupDisk = false
 	if err := os.Rename(temporaryDisk.Name(), targetDisk); err != nil {
 		return "", fmt.Errorf("failed to rename to %s: %w", targetDisk, err)
 	}
-	return targetDisk, nil
-}
 
-func getOrCreateDiskImage(ctx *vmRunCtx, imageName, imageDigest string) (string, error) {
-	diskImageName := strings.ReplaceAll(imageName, "/", "_")
-	diskPath := filepath.Join(ctx.cachedir, diskImageName)
-	f, err := os.Open(diskPath)
+	bootcVersion, err := getBootcVersion(ctx.cmd, imageName)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return "", err
-		}
+		logrus.Debugf("failed to determine bootc version for %s: %v", imageName, err)
 	}
-	defer f.Close()
-	buf := make([]byte, 4096)
-	len, err := unix.Fgetxattr(int(f.Fd()), imageMetaXattr, buf)
+
+	now := time.Now()
+	manifest.put(imageName, &cacheEntry{
+		Digest:       imageDigest,
+		Path:         targetDisk,
+		Size:         info.Size(),
+		CreatedAt:    now,
+		LastUsedAt:   now,
+		BootcVersion: bootcVersion,
+		KernelArgs:   bootcKernelArgs,
+	})
+	maxBytes, err := cacheSizeBytes()
 	if err != nil {
-		// If there's no xattr, just remove it
-		os.Remove(diskPath)
-		return createDiskImage(ctx, imageName, imageDigest, diskPath)
+		return "", err
 	}
-	bufTrimmed := buf[:len]
-	var serializedMeta diskFromContainerMeta
-	if err := json.Unmarshal(bufTrimmed, &serializedMeta); err != nil {
-		logrus.Warnf("failed to parse serialized meta from %s (%v) %v", diskPath, buf, err)
-		return createDiskImage(ctx, imageName, imageDigest, diskPath)
+	if err := evictLRU(ctx.cachedir, manifest, maxBytes, targetDisk); err != nil {
+		return "", err
 	}
+	return targetDisk, nil
+}
 
-	logrus.Debugf("previous disk digest: %s current digest: %s", serializedMeta.ImageDigest, imageDigest)
-	if serializedMeta.ImageDigest == imageDigest {
-		return diskPath, nil
+// getBootcVersion runs `bootc --version` inside imageName to record which
+// bootc built the cached disk, for display in `podman machine bootc cache ls`.
+func getBootcVersion(cmd *cobra.Command, imageName string) (string, error) {
+	c := podmanRecurse(cmd, []string{"run", "--rm", imageName, "bootc", "--version"})
+	buf := &bytes.Buffer{}
+	c.Stdout = buf
+	if err := c.Run(); err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// getOrCreateDiskImage returns the cached disk image for imageName at
+// imageDigest, rebuilding it only if it isn't already present. Disk images
+// are stored content-addressed by digest, so an image name that resolves to
+// a digest already built under a different tag reuses that same file without
+// a rebuild or a copy.
+//
+// The whole load-mutate-save cycle runs under withCacheLock: two `vmrun`s
+// against the same cache dir (e.g. building two different images at once)
+// would otherwise both load the manifest, mutate their own in-memory copy,
+// and save, with the second save silently discarding the first's entry.
+func getOrCreateDiskImage(ctx *vmRunCtx, imageName, imageDigest string) (string, error) {
+	var path string
+	err := withCacheLock(ctx.cachedir, func() error {
+		manifest, err := loadCacheManifest(ctx.cachedir)
+		if err != nil {
+			return err
+		}
+
+		if entry := manifest.get(imageName, imageDigest); entry != nil {
+			if _, err := os.Stat(entry.Path); err == nil {
+				logrus.Debugf("reusing cached disk %s for %s@%s", entry.Path, imageName, imageDigest)
+				entry.LastUsedAt = time.Now()
+				path = entry.Path
+				return manifest.save(ctx.cachedir)
+			}
+			logrus.Debugf("cached disk %s for %s@%s is missing on disk, rebuilding", entry.Path, imageName, imageDigest)
+		}
+
+		// Another image name may already have built this digest; record its
+		// content-addressed file for imageName too instead of rebuilding.
+		for _, digests := range manifest.Images {
+			if shared := digests[imageDigest]; shared != nil {
+				if _, err := os.Stat(shared.Path); err == nil {
+					logrus.Debugf("reusing cached disk %s for %s@%s", shared.Path, imageName, imageDigest)
+					reused := *shared
+					reused.LastUsedAt = time.Now()
+					manifest.put(imageName, &reused)
+					path = reused.Path
+					return manifest.save(ctx.cachedir)
+				}
+			}
+		}
+
+		path, err = createDiskImage(ctx, manifest, imageName, imageDigest)
+		if err != nil {
+			return err
+		}
+		return manifest.save(ctx.cachedir)
+	})
+	return path, err
+}
 
-	return createDiskImage(ctx, imageName, imageDigest, diskPath)
+// cacheSizeBytes parses the --cache-size flag, falling back to defaultCacheSize.
+func cacheSizeBytes() (int64, error) {
+	if options.cacheSize == "" {
+		return defaultCacheSize, nil
+	}
+	return units.RAMInBytes(options.cacheSize)
 }
 
 func vmrun(cmd *cobra.Command, args []string) error {
@@ -256,7 +322,12 @@ func vmrun(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("generated %s\n", disk)
 
-	// Create a cloned copy of the disk
+	// Run off a cloned copy of the cached disk rather than the cache entry
+	// itself: the cache is now a shared, content-addressed, persistent
+	// directory, so two concurrent vmrun invocations of the same image
+	// would otherwise boot from, and write to, the exact same backing file.
+	// cp -c asks APFS for a copy-on-write clone, so this is cheap even
+	// though diskSize is large.
 	diskdir := filepath.Dir(disk)
 	tempf, err := os.CreateTemp(diskdir, "bootc-vmrun")
 	if err != nil {
@@ -273,13 +344,27 @@ func vmrun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// SpawnTransientOpts also supports ExtraDisks, Networks, IgnitionPath,
+	// and CloudInitISO, but vmrun boots a single content-addressed raw disk
+	// with no ignition config and no network backend of its own, so none of
+	// those apply here; ReadyVsockPort is likewise unused since vmrun
+	// attaches to the console directly instead of polling for readiness.
+	// bootc's console kargs are baked into the disk itself by
+	// loopWrapperEntrypoint's `bootc install --karg` above, not passed
+	// through a bootloader cmdline: vfkit's EFI bootloader boots whatever
+	// bootloader the installed disk already has, the same as real hardware.
 	spawnopts := machine.SpawnTransientOpts{
-		Cpus:      2,
-		MemoryMiB: 2048,
-		Disk:      disk,
+		Cpus:      options.cpus,
+		MemoryMiB: options.memoryMiB,
+		Disk:      tempf.Name(),
 		Gui:       true,
 		VMDebug:   options.vmDebug,
+		Serial:    machine.SerialStdio,
 	}
 
-	return vmprovider.SpawnTransient(spawnopts)
+	vm, err := vmprovider.SpawnTransient(spawnopts)
+	if err != nil {
+		return err
+	}
+	return vm.Wait()
 }
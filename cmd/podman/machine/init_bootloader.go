@@ -0,0 +1,22 @@
+//go:build darwin
+
+package machine
+
+// init registers the bootloader-selection flags on `podman machine init`.
+// They're read directly off machine.InitOptions by name (Boot, Kernel,
+// Initrd, Cmdline) once the core init command populates that struct from
+// the CLI, so no further plumbing is needed here.
+//
+// --firmware is deliberately not exposed here: toVfkitBootloader rejects any
+// custom EFI firmware outright since vfkit has no way to load one, so a
+// --firmware flag would do nothing but fail, the same "flag whose only
+// behavior is to fail" pattern dropped for vmrun's --publish. opts.Firmware
+// itself stays on InitOptions for non-CLI consumers; it's just unreachable
+// from this command until vfkit can actually honor it.
+func init() {
+	flags := initCmd.Flags()
+	flags.StringVar(&initOpts.Boot, "boot", "efi", `Bootloader to use: "efi" or "linux"`)
+	flags.StringVar(&initOpts.Kernel, "kernel", "", "Path to a kernel to boot directly (requires --boot=linux)")
+	flags.StringVar(&initOpts.Initrd, "initrd", "", "Path to an initrd to pair with --kernel")
+	flags.StringVar(&initOpts.Cmdline, "cmdline", "", "Kernel command line to pair with --kernel")
+}
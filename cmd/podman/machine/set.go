@@ -0,0 +1,76 @@
+//go:build darwin
+
+package machine
+
+import (
+	"fmt"
+
+	"github.com/containers/podman/v4/cmd/podman/registry"
+	"github.com/containers/podman/v4/pkg/machine/applehv"
+	machinevirtprovider "github.com/containers/podman/v4/pkg/machine/provider"
+	"github.com/spf13/cobra"
+)
+
+var (
+	setCmd = &cobra.Command{
+		Use:   "set [options] MACHINE",
+		Short: "Change attributes of a machine",
+		Args:  cobra.ExactArgs(1),
+		RunE:  setMachine,
+	}
+	setOpts struct {
+		usbAdd    []string
+		usbRemove []string
+	}
+)
+
+func init() {
+	flags := setCmd.Flags()
+	flags.StringArrayVar(&setOpts.usbAdd, "usb-add", nil, "Attach a USB host device, e.g. vendor=0x1050,product=0x0407")
+	flags.StringArrayVar(&setOpts.usbRemove, "usb-remove", nil, "Detach a previously attached USB host device")
+	registry.Commands = append(registry.Commands, registry.CliCommand{
+		Command: setCmd,
+		Parent:  machineCmd,
+	})
+}
+
+func setMachine(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	provider, err := machinevirtprovider.Get()
+	if err != nil {
+		return err
+	}
+
+	vm, err := provider.LoadVMByName(name)
+	if err != nil {
+		return err
+	}
+
+	mm, ok := vm.(*applehv.MacMachine)
+	if !ok {
+		return fmt.Errorf("--usb-add and --usb-remove are only supported for applehv machines")
+	}
+
+	for _, spec := range setOpts.usbAdd {
+		dev, err := applehv.ParseUSBHostDevice(spec)
+		if err != nil {
+			return err
+		}
+		if err := mm.AddUSBHostDevice(dev); err != nil {
+			return err
+		}
+	}
+
+	for _, spec := range setOpts.usbRemove {
+		dev, err := applehv.ParseUSBHostDevice(spec)
+		if err != nil {
+			return err
+		}
+		if err := mm.RemoveUSBHostDevice(dev); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
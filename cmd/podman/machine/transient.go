@@ -0,0 +1,84 @@
+//go:build darwin
+
+package machine
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/containers/podman/v4/cmd/podman/registry"
+	"github.com/containers/podman/v4/pkg/machine/applehv"
+	machinevirtprovider "github.com/containers/podman/v4/pkg/machine/provider"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listTransientCmd = &cobra.Command{
+		Use:   "list-transient",
+		Short: "List running transient VMs spawned by this process",
+		Args:  cobra.NoArgs,
+		RunE:  listTransient,
+	}
+	stopTransientCmd = &cobra.Command{
+		Use:   "stop-transient ID",
+		Short: "Stop a running transient VM by ID",
+		Args:  cobra.ExactArgs(1),
+		RunE:  stopTransient,
+	}
+)
+
+func init() {
+	registry.Commands = append(registry.Commands,
+		registry.CliCommand{
+			Command: listTransientCmd,
+			Parent:  machineCmd,
+		},
+		registry.CliCommand{
+			Command: stopTransientCmd,
+			Parent:  machineCmd,
+		},
+	)
+}
+
+// transientProvider returns the applehv provider, the only one that
+// currently spawns transient VMs.
+func transientProvider() (*applehv.AppleHVVirtualization, error) {
+	provider, err := machinevirtprovider.Get()
+	if err != nil {
+		return nil, err
+	}
+	ahv, ok := provider.(*applehv.AppleHVVirtualization)
+	if !ok {
+		return nil, fmt.Errorf("list-transient and stop-transient are only supported on the applehv provider")
+	}
+	return ahv, nil
+}
+
+func listTransient(cmd *cobra.Command, args []string) error {
+	ahv, err := transientProvider()
+	if err != nil {
+		return err
+	}
+
+	vms, err := ahv.ListTransient()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "ID\tPID\tSTARTED")
+	for _, vm := range vms {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", vm.ID, vm.Pid, vm.StartedAt.Format("2006-01-02T15:04:05Z07:00"))
+	}
+	return nil
+}
+
+func stopTransient(cmd *cobra.Command, args []string) error {
+	ahv, err := transientProvider()
+	if err != nil {
+		return err
+	}
+	return ahv.StopTransient(args[0])
+}
@@ -0,0 +1,69 @@
+//go:build darwin
+
+package applehv
+
+import (
+	"fmt"
+
+	"github.com/containers/podman/v4/pkg/machine"
+	"github.com/containers/podman/v4/pkg/machine/vmconfigs"
+	vfConfig "github.com/crc-org/vfkit/pkg/config"
+)
+
+// defaultEFIVarsPath returns the EFI variable store path used when no
+// --boot/--kernel options are given to `podman machine init`.
+func defaultEFIVarsPath(dataDir, name string) string {
+	return fmt.Sprintf("%s/%ss", dataDir, name)
+}
+
+// newBootloaderConfig builds the BootloaderConfig to persist for a new
+// machine from opts, defaulting to an EFI bootloader with its own variable
+// store when the user didn't ask for direct Linux kernel boot.
+//
+// opts.Boot/Kernel/Initrd/Cmdline are populated from `podman machine
+// init`'s --boot/--kernel/--initrd/--cmdline flags; see
+// cmd/podman/machine/init_bootloader.go. opts.Firmware has no flag of its
+// own yet since toVfkitBootloader below rejects any non-empty value.
+func newBootloaderConfig(opts machine.InitOptions, dataDir string) (vmconfigs.BootloaderConfig, error) {
+	switch opts.Boot {
+	case "", "efi":
+		return vmconfigs.BootloaderConfig{
+			EFI: &vmconfigs.EFIBootloaderConfig{
+				VarsPath: defaultEFIVarsPath(dataDir, opts.Name),
+				Firmware: opts.Firmware,
+			},
+		}, nil
+	case "linux":
+		if opts.Kernel == "" {
+			return vmconfigs.BootloaderConfig{}, fmt.Errorf("--boot=linux requires --kernel")
+		}
+		return vmconfigs.BootloaderConfig{
+			LinuxKernel: &vmconfigs.LinuxBootloaderConfig{
+				Kernel:  opts.Kernel,
+				Initrd:  opts.Initrd,
+				Cmdline: opts.Cmdline,
+			},
+		}, nil
+	default:
+		return vmconfigs.BootloaderConfig{}, fmt.Errorf("unknown --boot value %q: must be \"efi\" or \"linux\"", opts.Boot)
+	}
+}
+
+// toVfkitBootloader converts a persisted BootloaderConfig into the vfkit
+// bootloader it describes.
+func toVfkitBootloader(bl vmconfigs.BootloaderConfig) (vfConfig.Bootloader, error) {
+	switch {
+	case bl.EFI != nil:
+		if bl.EFI.Firmware != "" {
+			// vfkit's EFI bootloader only ever boots its own built-in
+			// firmware; it has no way to supply a custom OVMF image, so
+			// reject this instead of silently booting with the default one.
+			return nil, fmt.Errorf("custom EFI firmware is not supported by the applehv provider")
+		}
+		return vfConfig.NewEFIBootloader(bl.EFI.VarsPath, true), nil
+	case bl.LinuxKernel != nil:
+		return vfConfig.NewLinuxBootloader(bl.LinuxKernel.Kernel, bl.LinuxKernel.Cmdline, bl.LinuxKernel.Initrd), nil
+	default:
+		return nil, fmt.Errorf("machine config has no bootloader set")
+	}
+}
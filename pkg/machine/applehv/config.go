@@ -118,9 +118,11 @@ func (v AppleHVVirtualization) LoadVMByName(name string) (machine.VM, error) {
 func (v AppleHVVirtualization) NewMachine(opts machine.InitOptions) (machine.VM, error) {
 	m := MacMachine{Name: opts.Name}
 
-	if len(opts.USBs) > 0 {
-		return nil, fmt.Errorf("USB host passthrough is not supported for applehv machines")
+	usbs, err := ParseUSBHostDevices(opts.USBs)
+	if err != nil {
+		return nil, err
 	}
+	m.USBs = usbs
 
 	configDir, err := machine.GetConfDir(define.AppleHvVirt)
 	if err != nil {
@@ -145,76 +147,144 @@ func (v AppleHVVirtualization) NewMachine(opts machine.InitOptions) (machine.VM,
 	// Set creation time
 	m.Created = time.Now()
 
+	bootloaderConfig, err := newBootloaderConfig(opts, dataDir)
+	if err != nil {
+		return nil, err
+	}
+
 	m.ResourceConfig = vmconfigs.ResourceConfig{
 		CPUs:     opts.CPUS,
 		DiskSize: opts.DiskSize,
 		// Diskpath will be needed
-		Memory: opts.Memory,
+		Memory:     opts.Memory,
+		Bootloader: bootloaderConfig,
+	}
+	bl, err := toVfkitBootloader(bootloaderConfig)
+	if err != nil {
+		return nil, err
 	}
-	bl := vfConfig.NewEFIBootloader(fmt.Sprintf("%s/%ss", dataDir, opts.Name), true)
 	m.Vfkit.VirtualMachine = vfConfig.NewVirtualMachine(uint(opts.CPUS), opts.Memory, bl)
 
+	// USB devices are added to the persisted device list here (and changed
+	// later only through AddUSBHostDevice/RemoveUSBHostDevice); Start() only
+	// needs to add the runtime-specific disk/serial/ready devices before
+	// exec'ing vfkit.
+	usbDevices, err := getUSBDevices(m.USBs)
+	if err != nil {
+		return nil, err
+	}
+	m.Vfkit.VirtualMachine.Devices = append(m.Vfkit.VirtualMachine.Devices, usbDevices...)
+
 	if err := m.writeConfig(); err != nil {
 		return nil, err
 	}
 	return m.loadFromFile()
 }
 
-func (v AppleHVVirtualization) SpawnTransient(opts machine.SpawnTransientOpts) error {
+// SpawnTransient launches an ephemeral vfkit VM per opts and returns a handle
+// to it immediately; the caller drives the VM's lifetime via the handle's
+// Wait and Signal methods instead of blocking inside SpawnTransient.
+func (v AppleHVVirtualization) SpawnTransient(opts machine.SpawnTransientOpts) (machine.TransientVM, error) {
 	cfg, err := config.Default()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	tempdir, err := os.MkdirTemp("", "podman-machine-applehv-")
+
+	id, err := newTransientID()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer os.RemoveAll(tempdir)
+
+	tempdir, err := os.MkdirTemp("", fmt.Sprintf("podman-machine-applehv-%s-", id))
+	if err != nil {
+		return nil, err
+	}
+	doCleanupTempdir := true
+	defer func() {
+		if doCleanupTempdir {
+			os.RemoveAll(tempdir)
+		}
+	}()
 	efidir := filepath.Join(tempdir, "efi")
 	if err := os.MkdirAll(efidir, 0755); err != nil {
-		return err
+		return nil, err
 	}
 
 	vfkitPath, err := cfg.FindHelperBinary("vfkit", false)
 	if err != nil {
-		return err
-	}
-	if err != nil {
-		return err
+		return nil, err
 	}
-	bl := vfConfig.NewEFIBootloader(filepath.Join(efidir, "applehv-transient"), true)
+	bl := vfConfig.NewEFIBootloader(filepath.Join(efidir, id), true)
 	vmconfig := vfConfig.NewVirtualMachine(uint(opts.Cpus), uint64(opts.MemoryMiB), bl)
 
 	devices, err := getBasicDevices()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	diskDevice, err := vfConfig.VirtioBlkNew(opts.Disk)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	devices = append(devices, diskDevice)
 
-	
+	for _, extraDisk := range opts.ExtraDisks {
+		dev, err := vfConfig.VirtioBlkNew(extraDisk.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach extra disk %s: %w", extraDisk.Path, err)
+		}
+		devices = append(devices, dev)
+	}
+
+	if opts.CloudInitISO != "" {
+		dev, err := vfConfig.VirtioBlkNew(opts.CloudInitISO)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach cloud-init ISO %s: %w", opts.CloudInitISO, err)
+		}
+		devices = append(devices, dev)
+	}
+
+	if opts.IgnitionPath != "" {
+		dev, err := getIgnitionVsockDevice(opts.IgnitionPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach ignition config %s: %w", opts.IgnitionPath, err)
+		}
+		devices = append(devices, dev)
+	}
+
+	if opts.ReadyVsockPort != 0 {
+		readyPath := filepath.Join(tempdir, id+".ready.sock")
+		dev, err := vfConfig.VirtioVsockNew(uint(opts.ReadyVsockPort), readyPath, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up ready vsock: %w", err)
+		}
+		devices = append(devices, dev)
+	}
+
+	netDevices, err := getNetworkDevices(opts.Networks)
+	if err != nil {
+		return nil, err
+	}
+	devices = append(devices, netDevices...)
+
 	vmconfig.Devices = append(vmconfig.Devices, devices...)
 
 	if opts.Gui {
 		debugdevs, err := getDebugDevices()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		vmconfig.Devices = append(vmconfig.Devices, debugdevs...)
-	} else {
-		serial, err :=  vfConfig.VirtioSerialNewStdio()
-		if err != nil {
-			return err
-		}
-		vmconfig.Devices = append(vmconfig.Devices, serial)
 	}
 
+	serialDevice, consolePath, err := getSerialDevice(opts.Serial, tempdir, id)
+	if err != nil {
+		return nil, err
+	}
+	vmconfig.Devices = append(vmconfig.Devices, serialDevice)
+
 	cmd, err := vmconfig.Cmd(vfkitPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Disable HTTP API
 	cmd.Args = append(cmd.Args, "--restful-uri=none://")
@@ -222,21 +292,108 @@ func (v AppleHVVirtualization) SpawnTransient(opts machine.SpawnTransientOpts) e
 		cmd.Args = append(cmd.Args, "--gui")
 	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stdin = os.Stdin
-	cmd.Stderr = os.Stderr
+	if consolePath == "" {
+		cmd.Stdout = os.Stdout
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+	}
 
 	if opts.VMDebug {
 		cmd.Args = append(cmd.Args, "--log-level", "debug")
 	}
 
+	logrus.Debugf("Spawning transient VM %s: %v", id, cmd.Args)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start transient VM %s: %w", id, err)
+	}
+	doCleanupTempdir = false
+	vm := registerTransient(id, consolePath, tempdir, cmd)
 
-	logrus.Debugf("Spawning vfkit: %v", cmd.Args)
-	if err := cmd.Run(); err != nil {
-		logrus.Infof("vfkit exited with error: %v", err)
-		
+	return vm, nil
+}
+
+// getSerialDevice builds the vfkit serial device for the requested mode,
+// returning the allocated console/pty path when one applies (empty for
+// stdio).
+func getSerialDevice(mode machine.SerialMode, tempdir, id string) (vfConfig.VirtioDevice, string, error) {
+	switch mode {
+	case machine.SerialPty, machine.SerialTCP:
+		return nil, "", fmt.Errorf("serial mode %q is not yet supported by the applehv provider", mode)
+	case machine.SerialLogfile:
+		consolePath := filepath.Join(tempdir, id+".console.log")
+		dev, err := vfConfig.VirtioSerialNew(consolePath)
+		if err != nil {
+			return nil, "", err
+		}
+		return dev, consolePath, nil
+	case machine.SerialStdio, "":
+		dev, err := vfConfig.VirtioSerialNewStdio()
+		if err != nil {
+			return nil, "", err
+		}
+		return dev, "", nil
+	default:
+		return nil, "", fmt.Errorf("unknown serial mode %q", mode)
+	}
+}
+
+// getNetworkDevices converts the requested NetworkSpecs into vfkit network
+// devices. vmnet-shared and vmnet-bridged both get vfkit's own NAT network
+// device; no host interface is bridged for vmnet-bridged. socket-vmnet
+// instead hands vfkit a pre-connected socket.
+func getNetworkDevices(networks []machine.NetworkSpec) ([]vfConfig.VirtioDevice, error) {
+	var devices []vfConfig.VirtioDevice
+	for _, network := range networks {
+		dev, err := vfConfig.VirtioNetNew("")
+		if err != nil {
+			return nil, fmt.Errorf("failed to add network device for %s: %w", network.Mode, err)
+		}
+		if network.Mode == machine.NetworkModeSocketVmnet {
+			dev.(*vfConfig.VirtioNet).Socket = network.Socket
+		}
+		devices = append(devices, dev)
+	}
+	return devices, nil
+}
+
+// AddUSBHostDevice attaches a new host USB device to the machine's
+// persisted config so it is passed through on the next start.
+func (m *MacMachine) AddUSBHostDevice(dev USBHostDevice) error {
+	for _, existing := range m.USBs {
+		if existing == dev {
+			return fmt.Errorf("USB device vendor=0x%x product=0x%x is already attached to %q", dev.VendorID, dev.ProductID, m.Name)
+		}
+	}
+	m.USBs = append(m.USBs, dev)
+	return m.syncUSBDevices()
+}
+
+// RemoveUSBHostDevice detaches a previously-configured host USB device
+// from the machine's persisted config.
+func (m *MacMachine) RemoveUSBHostDevice(dev USBHostDevice) error {
+	for i, existing := range m.USBs {
+		if existing == dev {
+			m.USBs = append(m.USBs[:i], m.USBs[i+1:]...)
+			return m.syncUSBDevices()
+		}
+	}
+	return fmt.Errorf("USB device vendor=0x%x product=0x%x is not attached to %q", dev.VendorID, dev.ProductID, m.Name)
+}
+
+// syncUSBDevices rebuilds m.Vfkit.VirtualMachine.Devices from the current
+// m.USBs and persists it. NewMachine never adds anything else to this list
+// up front (disk/serial/ready devices are only attached at Start() time), so
+// replacing it wholesale is safe and is what actually wires an added or
+// removed USB device into the vfkit command line on the next start; without
+// this, AddUSBHostDevice/RemoveUSBHostDevice would only ever update the JSON
+// bookkeeping.
+func (m *MacMachine) syncUSBDevices() error {
+	usbDevices, err := getUSBDevices(m.USBs)
+	if err != nil {
+		return err
 	}
-	return nil
+	m.Vfkit.VirtualMachine.Devices = usbDevices
+	return m.writeConfig()
 }
 
 func (v AppleHVVirtualization) RemoveAndCleanMachines() error {
@@ -0,0 +1,136 @@
+//go:build darwin
+
+package applehv
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const transientIDAlphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// TransientVM is the handle SpawnTransient returns for a running transient
+// VM, satisfying machine.TransientVM. It is also tracked by the in-process
+// transientRegistry so it can be enumerated and stopped via
+// ListTransient/StopTransient.
+type TransientVM struct {
+	// ID is the randomized identifier used to namespace this VM's
+	// tempdir, EFI store, and sockets, e.g. "vm-abcdefghij".
+	ID string
+	// Pid is the process ID of the running vfkit instance.
+	Pid int
+	// StartedAt is when the VM was spawned.
+	StartedAt time.Time
+	// ConsolePath is the allocated console/pty path for this VM's serial
+	// device, empty when Serial is stdio.
+	ConsolePath string
+
+	cmd *exec.Cmd
+
+	// waitCh is closed once the single goroutine that owns cmd.Wait()
+	// has collected the exit status; waitErr is only safe to read after
+	// waitCh is closed. (*exec.Cmd).Wait() may only ever be called once,
+	// so Wait() below blocks on this channel instead of calling it again.
+	waitCh  chan struct{}
+	waitErr error
+}
+
+// Wait blocks until the transient VM's vfkit process exits and returns its
+// exit status. It is safe to call Wait concurrently from multiple
+// goroutines; they all observe the same result.
+func (t *TransientVM) Wait() error {
+	<-t.waitCh
+	return t.waitErr
+}
+
+// Signal delivers sig to the transient VM's vfkit process.
+func (t *TransientVM) Signal(sig os.Signal) error {
+	return t.cmd.Process.Signal(sig)
+}
+
+var (
+	transientRegistryMu sync.Mutex
+	transientRegistry   = map[string]*TransientVM{}
+)
+
+// newTransientID returns a randomized transient VM identifier of the form
+// "vm-<10-char-alpha>", used to namespace a SpawnTransient invocation's
+// tempdir, EFI variable store, and vsock sockets so that concurrent
+// invocations never collide.
+func newTransientID() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate transient VM id: %w", err)
+	}
+	for i, v := range b {
+		b[i] = transientIDAlphabet[int(v)%len(transientIDAlphabet)]
+	}
+	return "vm-" + string(b), nil
+}
+
+// registerTransient adds a running transient VM to the in-process registry
+// and returns its handle. It is also the sole owner of cmd.Wait(): a
+// goroutine started here collects the exit status and removes tempdir once
+// the vfkit process exits, so TransientVM.Wait() never calls cmd.Wait()
+// itself and callers can call it as many times as they like.
+func registerTransient(id, consolePath, tempdir string, cmd *exec.Cmd) *TransientVM {
+	transientRegistryMu.Lock()
+	defer transientRegistryMu.Unlock()
+	vm := &TransientVM{
+		ID:          id,
+		Pid:         cmd.Process.Pid,
+		StartedAt:   time.Now(),
+		ConsolePath: consolePath,
+		cmd:         cmd,
+		waitCh:      make(chan struct{}),
+	}
+	transientRegistry[id] = vm
+	go func() {
+		vm.waitErr = cmd.Wait()
+		os.RemoveAll(tempdir)
+		unregisterTransient(id)
+		close(vm.waitCh)
+	}()
+	return vm
+}
+
+// unregisterTransient removes a transient VM from the in-process registry,
+// e.g. once its vfkit process has exited.
+func unregisterTransient(id string) {
+	transientRegistryMu.Lock()
+	defer transientRegistryMu.Unlock()
+	delete(transientRegistry, id)
+}
+
+// ListTransient returns the transient VMs currently tracked by this process.
+func (v AppleHVVirtualization) ListTransient() ([]*TransientVM, error) {
+	transientRegistryMu.Lock()
+	defer transientRegistryMu.Unlock()
+
+	vms := make([]*TransientVM, 0, len(transientRegistry))
+	for _, vm := range transientRegistry {
+		vms = append(vms, vm)
+	}
+	return vms, nil
+}
+
+// StopTransient stops a running transient VM spawned by this process by ID.
+// Only VMs in this process's registry are found; a VM spawned by another
+// podman process, such as a separate `podman machine bootc vmrun`, is not.
+func (v AppleHVVirtualization) StopTransient(id string) error {
+	transientRegistryMu.Lock()
+	vm, ok := transientRegistry[id]
+	transientRegistryMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no transient VM with id %q", id)
+	}
+	if err := vm.Signal(os.Kill); err != nil {
+		return fmt.Errorf("failed to stop transient VM %q: %w", id, err)
+	}
+	unregisterTransient(id)
+	return nil
+}
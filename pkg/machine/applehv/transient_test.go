@@ -0,0 +1,34 @@
+//go:build darwin
+
+package applehv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTransientID(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id, err := newTransientID()
+		if err != nil {
+			t.Fatalf("newTransientID() returned error: %v", err)
+		}
+		if !strings.HasPrefix(id, "vm-") {
+			t.Fatalf("newTransientID() = %q, want \"vm-\" prefix", id)
+		}
+		suffix := strings.TrimPrefix(id, "vm-")
+		if len(suffix) != 10 {
+			t.Fatalf("newTransientID() = %q, want 10-character suffix, got %d", id, len(suffix))
+		}
+		for _, r := range suffix {
+			if !strings.ContainsRune(transientIDAlphabet, r) {
+				t.Fatalf("newTransientID() = %q, contains character %q outside transientIDAlphabet", id, r)
+			}
+		}
+		if seen[id] {
+			t.Fatalf("newTransientID() returned duplicate id %q across %d calls", id, i+1)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,60 @@
+//go:build darwin
+
+package applehv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// USBHostDevice describes a host USB device that should be passed through to
+// the guest via vfkit's VZUSBController support.
+type USBHostDevice struct {
+	// VendorID is the USB vendor ID, e.g. 0x1050.
+	VendorID int
+	// ProductID is the USB product ID, e.g. 0x0407.
+	ProductID int
+}
+
+// ParseUSBHostDevice parses a single --usb, --usb-add or --usb-remove value of the form
+// "vendor=0x1234,product=0x5678" into a USBHostDevice.
+func ParseUSBHostDevice(spec string) (USBHostDevice, error) {
+	var dev USBHostDevice
+	for _, kv := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return dev, fmt.Errorf("invalid USB device spec %q: expected key=value", kv)
+		}
+		id, err := strconv.ParseUint(strings.TrimSpace(value), 0, 16)
+		if err != nil {
+			return dev, fmt.Errorf("invalid USB device spec %q: %w", kv, err)
+		}
+		switch strings.TrimSpace(key) {
+		case "vendor":
+			dev.VendorID = int(id)
+		case "product":
+			dev.ProductID = int(id)
+		default:
+			return dev, fmt.Errorf("invalid USB device spec %q: unknown key %q", kv, key)
+		}
+	}
+	if dev.VendorID == 0 || dev.ProductID == 0 {
+		return dev, fmt.Errorf("invalid USB device spec %q: vendor and product are both required", spec)
+	}
+	return dev, nil
+}
+
+// ParseUSBHostDevices parses a set of --usb values, as accepted by
+// `podman machine init`.
+func ParseUSBHostDevices(specs []string) ([]USBHostDevice, error) {
+	devices := make([]USBHostDevice, 0, len(specs))
+	for _, spec := range specs {
+		dev, err := ParseUSBHostDevice(spec)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, dev)
+	}
+	return devices, nil
+}
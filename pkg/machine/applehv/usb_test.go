@@ -0,0 +1,100 @@
+//go:build darwin
+
+package applehv
+
+import "testing"
+
+func TestParseUSBHostDevice(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    USBHostDevice
+		wantErr bool
+	}{
+		{
+			name: "valid hex",
+			spec: "vendor=0x1050,product=0x0407",
+			want: USBHostDevice{VendorID: 0x1050, ProductID: 0x0407},
+		},
+		{
+			name: "valid decimal",
+			spec: "vendor=4176,product=1031",
+			want: USBHostDevice{VendorID: 4176, ProductID: 1031},
+		},
+		{
+			name:    "missing product",
+			spec:    "vendor=0x1050",
+			wantErr: true,
+		},
+		{
+			name:    "missing vendor",
+			spec:    "product=0x0407",
+			wantErr: true,
+		},
+		{
+			name:    "malformed kv with no equals",
+			spec:    "vendor",
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			spec:    "vendor=0x1050,bus=0x1",
+			wantErr: true,
+		},
+		{
+			name:    "negative vendor id rejected",
+			spec:    "vendor=-1,product=0x0407",
+			wantErr: true,
+		},
+		{
+			name:    "id out of 16-bit range rejected",
+			spec:    "vendor=0x10000,product=0x0407",
+			wantErr: true,
+		},
+		{
+			name:    "empty spec",
+			spec:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUSBHostDevice(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseUSBHostDevice(%q) = %+v, want error", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseUSBHostDevice(%q) returned unexpected error: %v", tt.spec, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseUSBHostDevice(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUSBHostDevices(t *testing.T) {
+	devices, err := ParseUSBHostDevices([]string{"vendor=0x1050,product=0x0407", "vendor=0x05ac,product=0x12a8"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []USBHostDevice{
+		{VendorID: 0x1050, ProductID: 0x0407},
+		{VendorID: 0x05ac, ProductID: 0x12a8},
+	}
+	if len(devices) != len(want) {
+		t.Fatalf("got %d devices, want %d", len(devices), len(want))
+	}
+	for i := range want {
+		if devices[i] != want[i] {
+			t.Errorf("device %d = %+v, want %+v", i, devices[i], want[i])
+		}
+	}
+
+	if _, err := ParseUSBHostDevices([]string{"vendor=0x1050,product=0x0407", "not-a-valid-spec"}); err == nil {
+		t.Fatal("expected error from a malformed spec in the list, got nil")
+	}
+}
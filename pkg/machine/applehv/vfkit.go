@@ -3,6 +3,8 @@
 package applehv
 
 import (
+	"fmt"
+
 	"github.com/containers/podman/v4/pkg/machine"
 	vfConfig "github.com/crc-org/vfkit/pkg/config"
 )
@@ -40,6 +42,21 @@ func getDefaultDevices(imagePath, logPath, readyPath string) ([]vfConfig.VirtioD
 		return nil, err
 	}
 	devices = append(devices, disk, serial, readyDevice)
+
+	return devices, nil
+}
+
+// getUSBDevices converts the configured host USB devices into vfkit's
+// USB controller devices for passthrough.
+func getUSBDevices(usbs []USBHostDevice) ([]vfConfig.VirtioDevice, error) {
+	var devices []vfConfig.VirtioDevice
+	for _, usb := range usbs {
+		dev, err := vfConfig.USBHostDeviceNew(usb.VendorID, usb.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add USB device vendor=0x%x product=0x%x: %w", usb.VendorID, usb.ProductID, err)
+		}
+		devices = append(devices, dev)
+	}
 	return devices, nil
 }
 
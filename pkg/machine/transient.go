@@ -0,0 +1,80 @@
+package machine
+
+import "os"
+
+// DiskSpec describes an extra disk to attach to a transient VM, beyond the
+// primary disk given by SpawnTransientOpts.Disk.
+type DiskSpec struct {
+	// Path is the disk image file to attach.
+	Path string
+}
+
+// NetworkMode selects how a transient VM's network device is backed.
+type NetworkMode string
+
+const (
+	// NetworkModeVmnetShared attaches to the host's shared vmnet network.
+	NetworkModeVmnetShared NetworkMode = "vmnet-shared"
+	// NetworkModeVmnetBridged bridges onto a host network interface.
+	NetworkModeVmnetBridged NetworkMode = "vmnet-bridged"
+	// NetworkModeSocketVmnet hands the VM a pre-connected socket_vmnet
+	// client socket instead of asking vfkit to manage the network itself.
+	NetworkModeSocketVmnet NetworkMode = "socket-vmnet"
+)
+
+// NetworkSpec describes a network device to attach to a transient VM.
+type NetworkSpec struct {
+	Mode NetworkMode
+	// Socket is the path to a connected socket_vmnet client socket. Only
+	// meaningful when Mode is NetworkModeSocketVmnet.
+	Socket string
+}
+
+// SerialMode selects how a transient VM's serial console is exposed.
+type SerialMode string
+
+const (
+	// SerialStdio attaches the console to the calling process's stdio.
+	SerialStdio SerialMode = "stdio"
+	// SerialPty allocates a pty and returns its path as the console.
+	SerialPty SerialMode = "pty"
+	// SerialTCP exposes the console over a TCP socket.
+	SerialTCP SerialMode = "tcp"
+	// SerialLogfile appends console output to a file and returns its path.
+	SerialLogfile SerialMode = "logfile"
+)
+
+// SpawnTransientOpts configures an ephemeral VM launched via
+// VirtProvider.SpawnTransient, e.g. from `podman machine bootc vmrun`.
+type SpawnTransientOpts struct {
+	Cpus      uint16
+	MemoryMiB uint64
+	Disk      string
+	Gui       bool
+	VMDebug   bool
+
+	// ExtraDisks are attached in addition to Disk.
+	ExtraDisks []DiskSpec
+	// Networks are the network devices to attach; a transient VM with no
+	// entries has no network connectivity.
+	Networks []NetworkSpec
+	// IgnitionPath, if set, is served to the guest over a vsock device.
+	IgnitionPath string
+	// CloudInitISO, if set, is attached as an extra disk.
+	CloudInitISO string
+	// Serial selects how the VM's console is exposed.
+	Serial SerialMode
+	// ReadyVsockPort, if non-zero, adds a vsock device on that port for the
+	// guest's ready notification; SpawnTransient does not wait for it.
+	ReadyVsockPort uint32
+}
+
+// TransientVM is the handle VirtProvider.SpawnTransient returns for a
+// running transient VM; each provider supplies its own implementation.
+type TransientVM interface {
+	// Wait blocks until the transient VM exits and returns its result. It
+	// is safe to call concurrently from multiple goroutines.
+	Wait() error
+	// Signal delivers sig to the transient VM's process.
+	Signal(sig os.Signal) error
+}